@@ -0,0 +1,126 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	klog "k8s.io/klog/v2"
+)
+
+// amqpURL return the url of the dependency with the password
+func (t *Dependency) amqpURL() (string, error) {
+	password, err := t.password()
+
+	if err != nil {
+		return "", err
+	}
+
+	u := t.targetURL("queue", "exchange")
+
+	if t._url.User != nil && password != "" {
+		u.User = url.UserPassword(t._url.User.Username(), password)
+	}
+
+	return u.String(), nil
+}
+
+// amqpNotFound return true if the error means the vhost, the queue or the exchange doesn't exist yet
+func amqpNotFound(err error) bool {
+	var amqpError *amqp.Error
+
+	return err == amqp.ErrVhost || (errors.As(err, &amqpError) && (amqpError.Code == amqp.NotFound || amqpError.Code == amqp.NotAllowed))
+}
+
+// amqpDeclare check with a passive declare that the queue and the exchange exist
+func (t *Dependency) amqpDeclare(conn *amqp.Connection) error {
+	queue := t.option("queue")
+	exchange := t.option("exchange")
+
+	if queue == "" && exchange == "" {
+		return nil
+	}
+
+	channel, err := conn.Channel()
+
+	if err != nil {
+		return err
+	}
+
+	defer channel.Close()
+
+	if queue != "" {
+		if _, err = channel.QueueDeclarePassive(queue, false, false, false, false, nil); err != nil {
+			return err
+		}
+	}
+
+	if exchange != "" {
+		if err = channel.ExchangeDeclarePassive(exchange, amqp.ExchangeDirect, false, false, false, false, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Dependency) isAMQPReady(ctx context.Context, verbose bool) (bool, error) {
+	var err error
+
+	config := amqp.Config{
+		Dial: amqp.DefaultDial(t.timeout()),
+	}
+
+	if t._kind == "amqps" {
+		if config.TLSClientConfig, err = t.tlsConfig(); err != nil {
+			return false, err
+		}
+	}
+
+	amqpURL, err := t.amqpURL()
+
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := amqp.DialConfig(amqpURL, config)
+
+	if err == nil {
+		defer conn.Close()
+
+		err = t.amqpDeclare(conn)
+	}
+
+	if err != nil {
+		if amqpNotFound(err) {
+			t.failed()
+
+			if verbose {
+				klog.Infof("Dependency %v not ready, %v", t, err)
+			}
+
+			return false, nil
+		}
+
+		return t.unreachable(err, verbose)
+	}
+
+	return t.succeeded(verbose), nil
+}
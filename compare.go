@@ -0,0 +1,227 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semverRegexp match a semantic version like v1.2.3 or 1.2.3-rc.1
+var semverRegexp = regexp.MustCompile(`^v?(\d+)(\.\d+)*(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// validOperator return true if the operator is supported by compareValue
+func validOperator(op string) bool {
+	switch op {
+	case "=", "==", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+
+	return false
+}
+
+// validValueType return true if the type of value is supported by compareValue, empty means guessed from the values
+func validValueType(valueType string) bool {
+	switch valueType {
+	case "", "number", "semver", "string":
+		return true
+	}
+
+	return false
+}
+
+// compareSemver compare two semantic versions, return -1, 0 or 1
+func compareSemver(a, b string) int {
+	split := func(version string) ([]string, string) {
+		version = strings.SplitN(strings.TrimPrefix(version, "v"), "+", 2)[0]
+		parts := strings.SplitN(version, "-", 2)
+
+		if len(parts) > 1 {
+			return strings.Split(parts[0], "."), parts[1]
+		}
+
+		return strings.Split(parts[0], "."), ""
+	}
+
+	aNumbers, aPrerelease := split(a)
+	bNumbers, bPrerelease := split(b)
+
+	for i := 0; i < len(aNumbers) || i < len(bNumbers); i++ {
+		var x, y int
+
+		if i < len(aNumbers) {
+			x, _ = strconv.Atoi(aNumbers[i])
+		}
+
+		if i < len(bNumbers) {
+			y, _ = strconv.Atoi(bNumbers[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	// A pre-release version has a lower precedence than the release
+	switch {
+	case aPrerelease == bPrerelease:
+		return 0
+	case aPrerelease == "":
+		return 1
+	case bPrerelease == "":
+		return -1
+	}
+
+	return comparePrerelease(aPrerelease, bPrerelease)
+}
+
+// comparePrerelease compare the dot separated identifiers of two pre-release versions, return -1, 0 or 1.
+// Numeric identifiers are compared as numbers and have a lower precedence than alphanumeric identifiers.
+func comparePrerelease(a, b string) int {
+	aIdentifiers := strings.Split(a, ".")
+	bIdentifiers := strings.Split(b, ".")
+
+	for i := 0; i < len(aIdentifiers) && i < len(bIdentifiers); i++ {
+		x, errX := strconv.Atoi(aIdentifiers[i])
+		y, errY := strconv.Atoi(bIdentifiers[i])
+
+		switch {
+		case errX == nil && errY == nil:
+			if x < y {
+				return -1
+			} else if x > y {
+				return 1
+			}
+		case errX == nil:
+			return -1
+		case errY == nil:
+			return 1
+		default:
+			if result := strings.Compare(aIdentifiers[i], bIdentifiers[i]); result != 0 {
+				return result
+			}
+		}
+	}
+
+	// A larger set of identifiers has a higher precedence
+	switch {
+	case len(aIdentifiers) < len(bIdentifiers):
+		return -1
+	case len(aIdentifiers) > len(bIdentifiers):
+		return 1
+	}
+
+	return 0
+}
+
+// parseNumber parse a number compared by compareValue
+func parseNumber(value string) (float64, error) {
+	x, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a number", value)
+	}
+
+	return x, nil
+}
+
+// compareValue compare the actual value to the expected value with the operator.
+// Without type, numbers and semantic versions are compared by value, other values as strings.
+// A two parts version like 1.10 is a number unless the type is semver.
+func compareValue(actual, op, expected, valueType string) (bool, error) {
+	var result int
+	var err error
+
+	actual = strings.TrimSpace(actual)
+	expected = strings.TrimSpace(expected)
+
+	if valueType == "" {
+		_, errX := strconv.ParseFloat(actual, 64)
+		_, errY := strconv.ParseFloat(expected, 64)
+
+		switch {
+		case errX == nil && errY == nil:
+			valueType = "number"
+		case semverRegexp.MatchString(actual) && semverRegexp.MatchString(expected):
+			valueType = "semver"
+		default:
+			valueType = "string"
+		}
+	}
+
+	switch valueType {
+	case "number":
+		var x, y float64
+
+		if x, err = parseNumber(actual); err != nil {
+			return false, err
+		}
+
+		if y, err = parseNumber(expected); err != nil {
+			return false, err
+		}
+
+		// NaN, like a ratio without data, never satisfies the comparison
+		if math.IsNaN(x) || math.IsNaN(y) {
+			return false, nil
+		}
+
+		if x < y {
+			result = -1
+		} else if x > y {
+			result = 1
+		}
+	case "semver":
+		if !semverRegexp.MatchString(actual) {
+			return false, fmt.Errorf("%s is not a semantic version", actual)
+		}
+
+		if !semverRegexp.MatchString(expected) {
+			return false, fmt.Errorf("%s is not a semantic version", expected)
+		}
+
+		result = compareSemver(actual, expected)
+	case "string":
+		result = strings.Compare(actual, expected)
+	default:
+		return false, fmt.Errorf("Unsupported type %v", valueType)
+	}
+
+	switch op {
+	case "=", "==":
+		return result == 0, nil
+	case "!=":
+		return result != 0, nil
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	case ">=":
+		return result >= 0, nil
+	}
+
+	return false, fmt.Errorf("Unsupported operator %v", op)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import "testing"
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.10", "1.9", 1},
+		{"1.2", "1.2.0", 0},
+		{"2", "1.10.5", 1},
+		{"1.2.3-rc.1", "1.2.3", -1},
+		{"1.2.3", "1.2.3-rc.1", 1},
+		{"1.2.3-alpha", "1.2.3-beta", -1},
+		{"1.2.3-rc.2", "1.2.3-rc.10", -1},
+		{"1.2.3-rc.10", "1.2.3-rc.2", 1},
+		{"1.2.3-alpha", "1.2.3-alpha.1", -1},
+		{"1.2.3-alpha.1", "1.2.3-alpha.beta", -1},
+		{"1.2.3-1", "1.2.3-alpha", -1},
+		{"1.2.3+build.1", "1.2.3+build.2", 0},
+	}
+
+	for _, test := range tests {
+		if got := compareSemver(test.a, test.b); got != test.want {
+			t.Errorf("compareSemver(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestCompareValue(t *testing.T) {
+	tests := []struct {
+		actual, op, expected, valueType string
+		want                            bool
+		wantErr                         bool
+	}{
+		{"42", ">=", "42", "", true, false},
+		{"41", ">=", "42", "", false, false},
+		{" 10 ", ">", "9", "", true, false},
+		{"0.9", ">", "0.85", "", true, false},
+		{"1e3", "=", "1000", "", true, false},
+		{"NaN", ">=", "0.9", "", false, false},
+		{"NaN", "<=", "0.9", "", false, false},
+		{"NaN", "=", "NaN", "", false, false},
+		{"NaN", "!=", "0.9", "", false, false},
+		{"+Inf", ">", "1000", "", true, false},
+		{"1.10", ">", "1.9", "", false, false},
+		{"1.10", ">", "1.9", "semver", true, false},
+		{"1.10.0", ">", "1.9.2", "", true, false},
+		{"v1.24.3", ">=", "1.24", "", true, false},
+		{"1.2.3-rc.1", "<", "1.2.3", "", true, false},
+		{"abc", "<", "abd", "", true, false},
+		{"ready", "=", "ready", "", true, false},
+		{"ready", "!=", "", "", true, false},
+		{"10", "<", "9", "string", true, false},
+		{"abc", ">", "1", "number", false, true},
+		{"abc", ">", "1.0", "semver", false, true},
+		{"1", "~", "1", "", false, true},
+		{"1", "=", "1", "date", false, true},
+	}
+
+	for _, test := range tests {
+		got, err := compareValue(test.actual, test.op, test.expected, test.valueType)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("compareValue(%q, %q, %q, %q) error = %v, want error %v", test.actual, test.op, test.expected, test.valueType, err, test.wantErr)
+		} else if got != test.want {
+			t.Errorf("compareValue(%q, %q, %q, %q) = %v, want %v", test.actual, test.op, test.expected, test.valueType, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// ConsulServiceEntry an entry of /v1/health/service
+type ConsulServiceEntry struct {
+	Node struct {
+		Node string `json:"Node"`
+	} `json:"Node"`
+	Service struct {
+		ID string `json:"ID"`
+	} `json:"Service"`
+}
+
+func (t *Dependency) isValidConsul() {
+	if strings.Trim(t._url.Path, "/") == "" {
+		klog.Fatalf("Service not defined for dependency %v", t)
+	}
+
+	t.intOption("passing", 1)
+}
+
+func (t *Dependency) isConsulReady(ctx context.Context, verbose bool) (bool, error) {
+	var entries []ConsulServiceEntry
+
+	header := http.Header{}
+	token, err := t.readSecret("tokenFile")
+
+	if err != nil {
+		return false, err
+	}
+
+	if token != "" {
+		header.Set("X-Consul-Token", token)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	service := strings.Trim(t._url.Path, "/")
+	code, err := t.getJSON(ctx, t.serviceURL("/v1/health/service/"+service, url.Values{"passing": []string{"true"}}), header, &entries)
+
+	// A cluster without leader answers 500 with a plain text body
+	if code >= http.StatusInternalServerError {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, status:%d", t, code)
+		}
+
+		return false, nil
+	}
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	if code != http.StatusOK {
+		t.failed()
+
+		return false, fmt.Errorf("Unable to get the health of %v, status:%d", t, code)
+	}
+
+	passing := t.intOption("passing", 1)
+
+	if verbose {
+		klog.Infof("Dependency %v, %d/%d instances passing", t, len(entries), passing)
+	}
+
+	if len(entries) < passing {
+		t.failed()
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -11,9 +11,9 @@ type Context struct {
 	cancel context.CancelFunc
 }
 
-// NewContext return a context. Timeout is in seconds
+// NewContext return a context canceled after the timeout
 func NewContext(timeout time.Duration) *Context {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	return &Context{
 		ctx:    ctx,
 		cancel: cancel,
@@ -19,8 +19,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
+	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -33,15 +36,78 @@ type Dependency struct {
 	_kind      string
 	_namespace string
 	_name      string
+	_port      string
+	_selector  string
+	_url       *url.URL
+	_options   url.Values
+	_command   []string
+	_successes int
 	_retry     int
 }
 
 func makeDependency(maxRetry int, depend string, ignoreError bool) *Dependency {
-	v := strings.Split(depend, "/")
+	if strings.HasPrefix(depend, "exec:") {
+		return &Dependency{
+			_kind:    "exec",
+			_command: splitCommand(strings.TrimPrefix(depend, "exec:")),
+			_retry:   maxRetry,
+		}
+	}
+
+	if strings.Contains(depend, "://") {
+		if u, err := url.Parse(depend); err == nil && u.Scheme != "" {
+			options := u.Query()
+
+			// The query belongs to the request, the options are given in the fragment
+			if u.Scheme == "http" || u.Scheme == "https" {
+				options, err = url.ParseQuery(u.EscapedFragment())
+			}
+
+			if err == nil {
+				return &Dependency{
+					_kind:    u.Scheme,
+					_url:     u,
+					_options: options,
+					_retry:   maxRetry,
+				}
+			}
+		}
+	}
+
+	v := strings.SplitN(depend, "/", 2)
+
+	if len(v) == 2 && v[0] == "all" {
+		n := strings.SplitN(v[1], ":", 2)
+
+		if len(n) > 1 {
+			return &Dependency{
+				_kind:      v[0],
+				_namespace: n[0],
+				_selector:  n[1],
+				_retry:     maxRetry,
+			}
+		}
+
+		return &Dependency{
+			_kind:      v[0],
+			_namespace: n[0],
+			_retry:     maxRetry,
+		}
+	}
 
 	if len(v) == 2 {
 		n := strings.Split(v[1], ":")
 
+		if len(n) > 2 {
+			return &Dependency{
+				_kind:      v[0],
+				_namespace: n[0],
+				_name:      n[1],
+				_port:      n[2],
+				_retry:     maxRetry,
+			}
+		}
+
 		if len(n) > 1 {
 			return &Dependency{
 				_kind:      v[0],
@@ -54,7 +120,7 @@ func makeDependency(maxRetry int, depend string, ignoreError bool) *Dependency {
 		return &Dependency{
 			_kind:      v[0],
 			_namespace: namespace,
-			_name:      n[1],
+			_name:      n[0],
 			_retry:     maxRetry,
 		}
 	}
@@ -69,13 +135,61 @@ func makeDependency(maxRetry int, depend string, ignoreError bool) *Dependency {
 }
 
 func (t *Dependency) String() string {
+	if t._url != nil {
+		return t._url.Redacted()
+	}
+
+	if t._kind == "exec" {
+		args := make([]string, len(t._command))
+
+		for i, arg := range t._command {
+			if strings.ContainsAny(arg, " \t\n") {
+				arg = strconv.Quote(arg)
+			}
+
+			args[i] = arg
+		}
+
+		return t._kind + ":" + strings.Join(args, " ")
+	}
+
+	if t._kind == "all" {
+		if t._selector != "" {
+			return t._kind + "/" + t._namespace + ":" + t._selector
+		}
+
+		return t._kind + "/" + t._namespace
+	}
+
+	if t._port != "" {
+		return t._kind + "/" + t._namespace + ":" + t._name + ":" + t._port
+	}
+
 	return t._kind + "/" + t._namespace + ":" + t._name
 }
 
 func (t *Dependency) isValid(ctx context.Context, client *clientset.Clientset) {
 
+	if t._url != nil {
+		t.isValidURL()
+		return
+	}
+
+	if t._kind == "exec" {
+		t.isValidExec()
+		return
+	}
+
 	switch t._kind {
 	case "po", "deploy", "ds", "rc", "rs", "sts", "svc":
+	case "ds-local":
+		if nodeName == "" {
+			klog.Fatalf("NODE_NAME environment variable is required for dependency %v", t)
+		}
+	case "all":
+		if _, err := labels.Parse(t._selector); err != nil {
+			klog.Fatalf("Unable to parse label selector of dependency %v: %v", t, err)
+		}
 	default:
 		klog.Fatalf("Unknown resource type %v", t._kind)
 	}
@@ -84,6 +198,10 @@ func (t *Dependency) isValid(ctx context.Context, client *clientset.Clientset) {
 		klog.Fatalf("Namespace not defined for dependency %v", t._name)
 	}
 
+	if t._port != "" && t._kind != "svc" {
+		klog.Fatalf("Port %v is only allowed for svc dependency %v", t._port, t)
+	}
+
 	namespace, err := client.CoreV1().Namespaces().Get(ctx, t._namespace, metav1.GetOptions{})
 
 	if err != nil || namespace == nil {
@@ -91,21 +209,38 @@ func (t *Dependency) isValid(ctx context.Context, client *clientset.Clientset) {
 	}
 }
 
-func (t *Dependency) podReady(pod *core.Pod, verbose bool) (bool, error) {
-	ready := false
-	numOfContainer := len(pod.Status.ContainerStatuses)
-	numOfReady := 0
-
-	if pod.Status.Phase == core.PodRunning {
-		ready = true
-		for _, container := range pod.Status.ContainerStatuses {
-			if container.Ready && container.State.Running != nil {
-				numOfReady++
-			}
+func (t *Dependency) podReadyReason(pod *core.Pod) (bool, string) {
+	if pod.Status.Phase != core.PodRunning {
+		if pod.Status.Reason != "" {
+			return false, fmt.Sprintf("phase is %v, reason:%v", pod.Status.Phase, pod.Status.Reason)
 		}
+
+		return false, fmt.Sprintf("phase is %v", pod.Status.Phase)
 	}
 
-	return numOfContainer == numOfReady && ready, nil
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.Ready && container.State.Running != nil {
+			continue
+		}
+
+		if container.State.Waiting != nil {
+			return false, fmt.Sprintf("container %v is waiting, reason:%v", container.Name, container.State.Waiting.Reason)
+		}
+
+		if container.State.Terminated != nil {
+			return false, fmt.Sprintf("container %v is terminated, reason:%v", container.Name, container.State.Terminated.Reason)
+		}
+
+		return false, fmt.Sprintf("container %v is not ready", container.Name)
+	}
+
+	return true, ""
+}
+
+func (t *Dependency) podReady(pod *core.Pod, verbose bool) (bool, error) {
+	ready, _ := t.podReadyReason(pod)
+
+	return ready, nil
 }
 
 func (t *Dependency) isPodReady(ctx context.Context, client *clientset.Clientset, verbose bool) (bool, error) {
@@ -124,7 +259,9 @@ func (t *Dependency) isDeploymentReady(ctx context.Context, client *clientset.Cl
 	} else if deployment == nil {
 		return false, fmt.Errorf("The deployment %v doesn't exists", t)
 	} else {
-		return deployment.Status.Replicas == deployment.Status.ReadyReplicas, err
+		return t.workloadReady(deployment.Status.Replicas == deployment.Status.ReadyReplicas, desiredReplicas(deployment.Spec.Replicas), verbose, func() ([]core.Pod, error) {
+			return t.deploymentPods(ctx, client, deployment)
+		})
 	}
 }
 
@@ -138,13 +275,68 @@ func (t *Dependency) isDaemonSetReady(ctx context.Context, client *clientset.Cli
 	}
 }
 
+func (t *Dependency) isLocalDaemonSetReady(ctx context.Context, client *clientset.Clientset, verbose bool) (bool, error) {
+	var daemonset *apps.DaemonSet
+	var pods *core.PodList
+	var err error
+
+	if daemonset, err = client.AppsV1().DaemonSets(t._namespace).Get(ctx, t._name, metav1.GetOptions{}); err != nil {
+		return false, err
+	}
+
+	if daemonset == nil {
+		return false, fmt.Errorf("The daemonset %v doesn't exists", t)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(daemonset.Spec.Selector)
+
+	if err != nil {
+		return false, err
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: "spec.nodeName=" + nodeName,
+	}
+
+	if pods, err = client.CoreV1().Pods(t._namespace).List(ctx, listOptions); err != nil {
+		return false, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		if metav1.IsControlledBy(pod, daemonset) {
+			ready, reason := t.podReadyReason(pod)
+
+			if verbose {
+				if ready {
+					klog.Infof("Daemonset %v, pod:%v is ready on node:%v", t, pod.Name, nodeName)
+				} else {
+					klog.Infof("Daemonset %v, pod:%v not ready on node:%v, %s", t, pod.Name, nodeName, reason)
+				}
+			}
+
+			return ready, nil
+		}
+	}
+
+	if verbose {
+		klog.Infof("Daemonset %v, no pod scheduled on node:%v", t, nodeName)
+	}
+
+	return false, nil
+}
+
 func (t *Dependency) isReplicaSetReady(ctx context.Context, client *clientset.Clientset, verbose bool) (bool, error) {
 	if replicaset, err := client.AppsV1().ReplicaSets(t._namespace).Get(ctx, t._name, metav1.GetOptions{}); err != nil {
 		return false, err
 	} else if replicaset == nil {
 		return false, fmt.Errorf("The replicaset %v doesn't exists", t)
 	} else {
-		return replicaset.Status.Replicas == replicaset.Status.ReadyReplicas, nil
+		return t.workloadReady(replicaset.Status.Replicas == replicaset.Status.ReadyReplicas, desiredReplicas(replicaset.Spec.Replicas), verbose, func() ([]core.Pod, error) {
+			return t.ownedPods(ctx, client, replicaset.Spec.Selector, replicaset.UID)
+		})
 	}
 }
 
@@ -154,7 +346,9 @@ func (t *Dependency) isReplicationControllerReady(ctx context.Context, client *c
 	} else if replicationcontroller == nil {
 		return false, fmt.Errorf("The replicationcontroller %v doesn't exists", t)
 	} else {
-		return replicationcontroller.Status.Replicas == replicationcontroller.Status.ReadyReplicas, nil
+		return t.workloadReady(replicationcontroller.Status.Replicas == replicationcontroller.Status.ReadyReplicas, desiredReplicas(replicationcontroller.Spec.Replicas), verbose, func() ([]core.Pod, error) {
+			return t.ownedPods(ctx, client, &metav1.LabelSelector{MatchLabels: replicationcontroller.Spec.Selector}, replicationcontroller.UID)
+		})
 	}
 }
 
@@ -164,16 +358,16 @@ func (t *Dependency) isStatefulSetsReady(ctx context.Context, client *clientset.
 	} else if stateful == nil {
 		return false, fmt.Errorf("The stateful %v doesn't exists", t)
 	} else {
-		return stateful.Status.Replicas == stateful.Status.ReadyReplicas, nil
+		return t.workloadReady(stateful.Status.Replicas == stateful.Status.ReadyReplicas, desiredReplicas(stateful.Spec.Replicas), verbose, func() ([]core.Pod, error) {
+			return t.ownedPods(ctx, client, stateful.Spec.Selector, stateful.UID)
+		})
 	}
 }
 
 func (t *Dependency) isServiceReady(ctx context.Context, client *clientset.Clientset, verbose bool) (bool, error) {
 	var service *core.Service
-	var pods *core.PodList
 	var err error
 	var ready bool
-	var numOfReady int
 
 	if service, err = client.CoreV1().Services(t._namespace).Get(ctx, t._name, metav1.GetOptions{}); err != nil {
 		return false, err
@@ -183,6 +377,27 @@ func (t *Dependency) isServiceReady(ctx context.Context, client *clientset.Clien
 		return false, fmt.Errorf("The service %v doesn't exists", t)
 	}
 
+	if zoneEndpoints > 0 {
+		ready, err = t.isServiceZoneReady(ctx, client, service, verbose)
+	} else if t._port != "" {
+		ready, err = t.isServicePortReady(ctx, client, service, verbose)
+	} else {
+		ready, err = t.isServicePodsReady(ctx, client, service, verbose)
+	}
+
+	if err != nil || !ready || connectEndpoints == 0 {
+		return ready, err
+	}
+
+	return t.isServiceReachable(ctx, client, service, verbose)
+}
+
+func (t *Dependency) isServicePodsReady(ctx context.Context, client *clientset.Clientset, service *core.Service, verbose bool) (bool, error) {
+	var pods *core.PodList
+	var err error
+	var ready bool
+	var numOfReady int
+
 	set := labels.Set(service.Spec.Selector)
 
 	if pods, err = client.CoreV1().Pods(t._namespace).List(ctx, metav1.ListOptions{LabelSelector: set.String()}); err != nil {
@@ -230,6 +445,8 @@ func (t *Dependency) ready(ctx context.Context, client *clientset.Clientset, ver
 			return t.isDeploymentReady(ctx, client, verbose)
 		case "ds":
 			return t.isDaemonSetReady(ctx, client, verbose)
+		case "ds-local":
+			return t.isLocalDaemonSetReady(ctx, client, verbose)
 		case "rs":
 			return t.isReplicaSetReady(ctx, client, verbose)
 		case "rc":
@@ -238,6 +455,48 @@ func (t *Dependency) ready(ctx context.Context, client *clientset.Clientset, ver
 			return t.isStatefulSetsReady(ctx, client, verbose)
 		case "svc":
 			return t.isServiceReady(ctx, client, verbose)
+		case "all":
+			return t.isNamespaceReady(ctx, client, verbose)
+		case "tcp":
+			return t.isTCPReady(ctx, verbose)
+		case "http", "https":
+			return t.isHTTPReady(ctx, verbose)
+		case "grpc":
+			return t.isGRPCReady(ctx, verbose)
+		case "dns":
+			return t.isDNSReady(ctx, verbose)
+		case "tls":
+			return t.isTLSReady(ctx, verbose)
+		case "postgres", "postgresql":
+			return t.isPostgresReady(ctx, verbose)
+		case "mysql":
+			return t.isMySQLReady(ctx, verbose)
+		case "redis", "rediss":
+			return t.isRedisReady(ctx, verbose)
+		case "mongodb":
+			return t.isMongoDBReady(ctx, verbose)
+		case "kafka":
+			return t.isKafkaReady(ctx, verbose)
+		case "amqp", "amqps":
+			return t.isAMQPReady(ctx, verbose)
+		case "es":
+			return t.isElasticsearchReady(ctx, verbose)
+		case "etcd":
+			return t.isEtcdReady(ctx, verbose)
+		case "prom":
+			return t.isPrometheusReady(ctx, verbose)
+		case "file":
+			return t.isFileReady(ctx, verbose)
+		case "unix":
+			return t.isUnixSocketReady(ctx, verbose)
+		case "exec":
+			return t.isExecReady(ctx, verbose)
+		case "vault":
+			return t.isVaultReady(ctx, verbose)
+		case "zk":
+			return t.isZookeeperReady(ctx, verbose)
+		case "consul":
+			return t.isConsulReady(ctx, verbose)
 		}
 	}
 
@@ -259,3 +518,11 @@ func (t *Dependency) namespace() string {
 func (t *Dependency) name() string {
 	return t._name
 }
+
+func (t *Dependency) port() string {
+	return t._port
+}
+
+func (t *Dependency) selector() string {
+	return t._selector
+}
@@ -81,8 +81,12 @@ func (t *DependencyList) ready(ctx context.Context, client *clientset.Clientset,
 				if !ignoreError {
 					return false, err
 				}
-			} else if verbose {
-				klog.Infof("Will retry %v dependency", depend.String())
+			} else {
+				if verbose {
+					klog.Infof("Will retry %v dependency", depend.String())
+				}
+
+				t.dependencies = append(t.dependencies, depend)
 			}
 		} else if ready {
 			klog.Infof("The dependency %v is ready", depend.String())
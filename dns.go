@@ -0,0 +1,155 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+func (t *Dependency) isValidDNS() {
+	switch t.recordType() {
+	case "A", "AAAA", "SRV", "CNAME":
+	default:
+		klog.Fatalf("Unknown record type %v for dependency %v", t.option("type"), t)
+	}
+
+	t.intOption("min", 1)
+}
+
+// recordType return the type of DNS record to resolve, default A
+func (t *Dependency) recordType() string {
+	if recordType := t.option("type"); recordType != "" {
+		return strings.ToUpper(recordType)
+	}
+
+	return "A"
+}
+
+// resolver return the resolver of the pod or a resolver using the server given by the option server
+func (t *Dependency) resolver() *net.Resolver {
+	server := t.option("server")
+
+	if server == "" {
+		return net.DefaultResolver
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: t.timeout()}
+
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// lookup return the records found for the dependency
+func (t *Dependency) lookup(ctx context.Context) ([]string, error) {
+	var records []string
+
+	resolver := t.resolver()
+	name := t._url.Hostname()
+
+	switch t.recordType() {
+	case "A", "AAAA":
+		network := "ip4"
+
+		if t.recordType() == "AAAA" {
+			network = "ip6"
+		}
+
+		addresses, err := resolver.LookupIP(ctx, network, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, address := range addresses {
+			records = append(records, address.String())
+		}
+	case "SRV":
+		_, addresses, err := resolver.LookupSRV(ctx, "", "", name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, address := range addresses {
+			records = append(records, net.JoinHostPort(address.Target, strconv.Itoa(int(address.Port))))
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSuffix(cname, ".") != strings.TrimSuffix(name, ".") {
+			records = append(records, cname)
+		}
+	}
+
+	return records, nil
+}
+
+func (t *Dependency) isDNSReady(ctx context.Context, verbose bool) (bool, error) {
+	var dnsError *net.DNSError
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	records, err := t.lookup(ctx)
+
+	if err != nil {
+		// A SERVFAIL while the resolver or the service is starting
+		if errors.As(err, &dnsError) && dnsError.IsTemporary {
+			t.failed()
+
+			if verbose {
+				klog.Infof("Dependency %v not ready, %v", t, err)
+			}
+
+			return false, nil
+		}
+
+		return t.unreachable(err, verbose)
+	}
+
+	required := t.intOption("min", 1)
+
+	if verbose {
+		klog.Infof("Dependency %v, %d/%d records found: %v", t, len(records), required, records)
+	}
+
+	if len(records) < required {
+		t.failed()
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	klog "k8s.io/klog/v2"
+)
+
+// esHealthStatus order the cluster status from the worst to the best
+var esHealthStatus = map[string]int{
+	"red":    0,
+	"yellow": 1,
+	"green":  2,
+}
+
+// ElasticsearchHealth the response of _cluster/health
+type ElasticsearchHealth struct {
+	ClusterName string `json:"cluster_name"`
+	Status      string `json:"status"`
+	TimedOut    bool   `json:"timed_out"`
+}
+
+func (t *Dependency) isValidElasticsearch() {
+	if _, found := esHealthStatus[t.requiredHealthStatus()]; !found {
+		klog.Fatalf("Unknown status %v for dependency %v", t.option("status"), t)
+	}
+}
+
+// requiredHealthStatus return the minimum status of the cluster, default green
+func (t *Dependency) requiredHealthStatus() string {
+	if status := t.option("status"); status != "" {
+		return status
+	}
+
+	return "green"
+}
+
+// elasticsearchHeader return the authorization header from the api key or the basic auth credentials
+func (t *Dependency) elasticsearchHeader() (http.Header, error) {
+	header := http.Header{}
+
+	apiKey, err := t.readSecret("apiKeyFile")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey != "" {
+		header.Set("Authorization", "ApiKey "+apiKey)
+
+		return header, nil
+	}
+
+	username, err := t.username()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if username != "" {
+		password, err := t.password()
+
+		if err != nil {
+			return nil, err
+		}
+
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+
+	return header, nil
+}
+
+func (t *Dependency) isElasticsearchReady(ctx context.Context, verbose bool) (bool, error) {
+	var health ElasticsearchHealth
+
+	header, err := t.elasticsearchHeader()
+
+	if err != nil {
+		return false, err
+	}
+
+	path := "/_cluster/health"
+
+	if index := t.option("index"); index != "" {
+		path += "/" + index
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	code, err := t.getJSON(ctx, t.serviceURL(path, url.Values{}), header, &health)
+
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		t.failed()
+
+		return false, fmt.Errorf("Access denied to %v, status:%d", t, code)
+	case code >= http.StatusInternalServerError || code == http.StatusNotFound || code == http.StatusRequestTimeout:
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, status:%d", t, code)
+		}
+
+		return false, nil
+	case err != nil:
+		return t.unreachable(err, verbose)
+	}
+
+	if status, found := esHealthStatus[health.Status]; !found || status < esHealthStatus[t.requiredHealthStatus()] {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, cluster %s status is %s", t, health.ClusterName, health.Status)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	core "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog/v2"
+)
+
+// ServiceEndpoint an endpoint of a service published by an EndpointSlice
+type ServiceEndpoint struct {
+	name      string
+	addresses []string
+	ports     []discovery.EndpointPort
+	ready     bool
+	zone      string
+	hints     []string
+}
+
+func (e *ServiceEndpoint) String() string {
+	if e.name != "" {
+		return e.name
+	}
+
+	return fmt.Sprintf("%v", e.addresses)
+}
+
+// servicePort return the port of the service matching the port name or number of the dependency
+func (t *Dependency) servicePort(service *core.Service) (*core.ServicePort, error) {
+	for i := range service.Spec.Ports {
+		port := &service.Spec.Ports[i]
+
+		if port.Name == t._port || strconv.Itoa(int(port.Port)) == t._port {
+			return port, nil
+		}
+	}
+
+	return nil, fmt.Errorf("The service %v doesn't expose the port %v", t, t._port)
+}
+
+// serviceEndpoints return the endpoints of the service from the EndpointSlices.
+// When a port is defined by the dependency, only the endpoints publishing this port are returned.
+func (t *Dependency) serviceEndpoints(ctx context.Context, client *clientset.Clientset, service *core.Service) ([]*ServiceEndpoint, error) {
+	var servicePort *core.ServicePort
+	var slices *discovery.EndpointSliceList
+	var err error
+
+	if t._port != "" {
+		if servicePort, err = t.servicePort(service); err != nil {
+			return nil, err
+		}
+	}
+
+	selector := discovery.LabelServiceName + "=" + service.Name
+
+	if slices, err = client.DiscoveryV1().EndpointSlices(t._namespace).List(ctx, metav1.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*ServiceEndpoint, 0)
+
+	for _, slice := range slices.Items {
+		ports := make([]discovery.EndpointPort, 0, len(slice.Ports))
+
+		for _, port := range slice.Ports {
+			if port.Port == nil {
+				continue
+			}
+
+			if servicePort == nil || (port.Name != nil && *port.Name == servicePort.Name) {
+				ports = append(ports, port)
+			}
+		}
+
+		if servicePort != nil && len(ports) == 0 {
+			continue
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			e := &ServiceEndpoint{
+				addresses: endpoint.Addresses,
+				ports:     ports,
+				ready:     endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready,
+			}
+
+			if endpoint.TargetRef != nil {
+				e.name = endpoint.TargetRef.Name
+			}
+
+			if endpoint.Zone != nil {
+				e.zone = *endpoint.Zone
+			}
+
+			if endpoint.Hints != nil {
+				for _, hint := range endpoint.Hints.ForZones {
+					e.hints = append(e.hints, hint.Name)
+				}
+			}
+
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// reachable dial every TCP port of each address of the endpoint, an endpoint without TCP port is not reachable
+func (e *ServiceEndpoint) reachable(ctx context.Context) error {
+	var numOfDialed int
+
+	dialer := net.Dialer{Timeout: probeTimeout}
+
+	for _, address := range e.addresses {
+		for _, port := range e.ports {
+			if port.Protocol != nil && *port.Protocol != core.ProtocolTCP {
+				continue
+			}
+
+			conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, strconv.Itoa(int(*port.Port))))
+
+			if err != nil {
+				return err
+			}
+
+			conn.Close()
+			numOfDialed++
+		}
+	}
+
+	if numOfDialed == 0 {
+		return fmt.Errorf("no TCP port to dial")
+	}
+
+	return nil
+}
+
+// isServiceReachable dial the ready endpoints of the service until the required number of endpoints is reachable
+func (t *Dependency) isServiceReachable(ctx context.Context, client *clientset.Clientset, service *core.Service, verbose bool) (bool, error) {
+	var numOfReady int
+	var numOfReachable int
+
+	endpoints, err := t.serviceEndpoints(ctx, client, service)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, endpoint := range endpoints {
+		if !endpoint.ready || (zoneEndpoints > 0 && !endpoint.inZone(localZone)) {
+			continue
+		}
+
+		numOfReady++
+
+		if numOfReachable >= connectEndpoints {
+			continue
+		}
+
+		if err = endpoint.reachable(ctx); err != nil {
+			if verbose {
+				klog.Infof("Service %v, endpoint:%v is unreachable, reason: %v", t, endpoint, err)
+			}
+		} else {
+			numOfReachable++
+
+			if verbose {
+				klog.Infof("Service %v, endpoint:%v is reachable", t, endpoint)
+			}
+		}
+	}
+
+	required := connectEndpoints
+
+	if required > numOfReady {
+		required = numOfReady
+	}
+
+	if verbose {
+		klog.Infof("Service %v, %d/%d endpoints reachable", t, numOfReachable, required)
+	}
+
+	return numOfReady > 0 && numOfReachable >= required, nil
+}
+
+// inZone return true if the endpoint is located in the zone or hinted for this zone
+func (e *ServiceEndpoint) inZone(zone string) bool {
+	if e.zone == zone {
+		return true
+	}
+
+	for _, hint := range e.hints {
+		if hint == zone {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Dependency) isServiceZoneReady(ctx context.Context, client *clientset.Clientset, service *core.Service, verbose bool) (bool, error) {
+	var numOfReady int
+
+	endpoints, err := t.serviceEndpoints(ctx, client, service)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, endpoint := range endpoints {
+		if !endpoint.inZone(localZone) {
+			if verbose {
+				klog.Infof("Service %v, endpoint:%v is not in zone:%v", t, endpoint, localZone)
+			}
+		} else if endpoint.ready {
+			numOfReady++
+
+			if verbose {
+				klog.Infof("Service %v, endpoint:%v is ready in zone:%v", t, endpoint, localZone)
+			}
+		} else if verbose {
+			klog.Infof("Service %v, endpoint:%v not ready in zone:%v", t, endpoint, localZone)
+		}
+	}
+
+	if verbose {
+		klog.Infof("Service %v, %d/%d ready endpoints in zone:%v", t, numOfReady, zoneEndpoints, localZone)
+	}
+
+	return numOfReady >= zoneEndpoints, nil
+}
+
+func (t *Dependency) isServicePortReady(ctx context.Context, client *clientset.Clientset, service *core.Service, verbose bool) (bool, error) {
+	var numOfReady int
+
+	endpoints, err := t.serviceEndpoints(ctx, client, service)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.ready {
+			numOfReady++
+
+			if verbose {
+				klog.Infof("Service %v, endpoint:%v is ready", t, endpoint)
+			}
+		} else if verbose {
+			klog.Infof("Service %v, endpoint:%v not ready", t, endpoint)
+		}
+	}
+
+	return numOfReady > 0 && numOfReady == len(endpoints), nil
+}
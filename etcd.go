@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	klog "k8s.io/klog/v2"
+)
+
+// etcdMemberHealth return why the member is not healthy, empty if it has a leader, no errors and no alarms.
+// Each member has its own timeout so a blackholed member doesn't consume the time of the next ones.
+func (t *Dependency) etcdMemberHealth(ctx context.Context, endpoint string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	conn, err := t.dialGRPC(ctx, endpoint)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	maintenance := pb.NewMaintenanceClient(conn)
+	status, err := maintenance.Status(ctx, &pb.StatusRequest{})
+
+	if err != nil {
+		return "", err
+	}
+
+	if status.Leader == 0 {
+		return "no leader", nil
+	}
+
+	if len(status.Errors) > 0 {
+		return fmt.Sprintf("errors: %v", strings.Join(status.Errors, ", ")), nil
+	}
+
+	alarms, err := maintenance.Alarm(ctx, &pb.AlarmRequest{Action: pb.AlarmRequest_GET})
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(alarms.Alarms) > 0 {
+		return fmt.Sprintf("alarms: %v", alarms.Alarms), nil
+	}
+
+	return "", nil
+}
+
+func (t *Dependency) isEtcdReady(ctx context.Context, verbose bool) (bool, error) {
+	var numOfHealthy int
+	var lastError error
+
+	endpoints := strings.Split(t._url.Host, ",")
+	quorum := len(endpoints)/2 + 1
+
+	for _, endpoint := range endpoints {
+		reason, err := t.etcdMemberHealth(ctx, endpoint)
+
+		if err != nil {
+			// A member not reachable yet is not healthy, other failures like TLS or auth are errors
+			if !grpcUnavailable(err) {
+				lastError = err
+			}
+
+			reason = err.Error()
+		}
+
+		if reason != "" {
+			if verbose {
+				klog.Infof("Dependency %v, member:%v is not healthy, reason: %v", t, endpoint, reason)
+			}
+		} else {
+			numOfHealthy++
+
+			if verbose {
+				klog.Infof("Dependency %v, member:%v is healthy", t, endpoint)
+			}
+		}
+	}
+
+	if verbose {
+		klog.Infof("Dependency %v, %d/%d healthy members, quorum:%d", t, numOfHealthy, len(endpoints), quorum)
+	}
+
+	if numOfHealthy < quorum {
+		t.failed()
+
+		return false, lastError
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// maxOutputSize is the maximum size of the output of a command reported in the log
+const maxOutputSize = 1024
+
+func (t *Dependency) isValidExec() {
+	if len(t._command) == 0 {
+		klog.Fatalf("Command not defined for dependency %v", t)
+	}
+
+	if _, err := exec.LookPath(t._command[0]); err != nil {
+		klog.Fatalf("Command not found for dependency %v, reason: %v", t, err)
+	}
+}
+
+// splitCommand split a command line into arguments, single and double quotes group words
+func splitCommand(command string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+
+	inArg := false
+
+	for _, c := range command {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+// truncateOutput limit the output of a command to maxOutputSize
+func truncateOutput(output []byte) string {
+	if len(output) > maxOutputSize {
+		return strings.TrimSpace(string(output[:maxOutputSize])) + "...(truncated)"
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func (t *Dependency) isExecReady(ctx context.Context, verbose bool) (bool, error) {
+	var exitError *exec.ExitError
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, t._command[0], t._command[1:]...).CombinedOutput()
+
+	if verbose && len(output) > 0 {
+		klog.Infof("Dependency %v output: %s", t, truncateOutput(output))
+	}
+
+	if err != nil {
+		t.failed()
+
+		if ctx.Err() == context.DeadlineExceeded {
+			if verbose {
+				klog.Infof("Dependency %v not ready, timed out after %v", t, t.timeout())
+			}
+
+			return false, nil
+		}
+
+		if errors.As(err, &exitError) {
+			if verbose {
+				klog.Infof("Dependency %v not ready, exit code:%d", t, exitError.ExitCode())
+			}
+
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return t.succeeded(verbose), nil
+}
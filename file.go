@@ -0,0 +1,118 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+
+	klog "k8s.io/klog/v2"
+)
+
+// fileNotReadyReason return why the file is not ready, empty if ready
+func (t *Dependency) fileNotReadyReason() (string, error) {
+	info, err := os.Stat(t._url.Path)
+
+	if os.IsNotExist(err) {
+		return "the file doesn't exist", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !info.Mode().IsRegular() {
+		return "not a regular file", nil
+	}
+
+	if info.Size() == 0 {
+		return "the file is empty", nil
+	}
+
+	if re := t.regexpOption("content"); re != nil {
+		file, err := os.Open(t._url.Path)
+
+		if err != nil {
+			return "", err
+		}
+
+		defer file.Close()
+
+		content, err := io.ReadAll(io.LimitReader(file, maxBodySize))
+
+		if err != nil {
+			return "", err
+		}
+
+		if !re.Match(content) {
+			return "the content doesn't match " + re.String(), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (t *Dependency) isFileReady(ctx context.Context, verbose bool) (bool, error) {
+	reason, err := t.fileNotReadyReason()
+
+	if err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
+
+func (t *Dependency) isUnixSocketReady(ctx context.Context, verbose bool) (bool, error) {
+	dialer := net.Dialer{Timeout: t.timeout()}
+
+	conn, err := dialer.DialContext(ctx, "unix", t._url.Path)
+
+	if err != nil {
+		// The socket is not yet created by the sidecar
+		if errors.Is(err, os.ErrNotExist) {
+			t.failed()
+
+			if verbose {
+				klog.Infof("Dependency %v not ready, %v", t, err)
+			}
+
+			return false, nil
+		}
+
+		// A stale socket left by a restarting sidecar refuses the connection
+		return t.unreachable(err, verbose)
+	}
+
+	conn.Close()
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	klog "k8s.io/klog/v2"
+)
+
+// dialGRPC return a gRPC connection to the host, TLS is enabled with the option tls
+func (t *Dependency) dialGRPC(ctx context.Context, host string) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+
+	if t.boolOption("tls") {
+		tlsConfig, err := t.tlsConfig()
+
+		if err != nil {
+			return nil, err
+		}
+
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	return grpc.DialContext(ctx, host, grpc.WithTransportCredentials(creds))
+}
+
+// grpcUnavailable return true if the error means the server is starting or the service is not registered yet.
+// A failed TLS handshake is also reported as unavailable by gRPC, it is not retried.
+func grpcUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.NotFound, codes.DeadlineExceeded:
+		return true
+	case codes.Unavailable:
+		return !strings.Contains(status.Convert(err).Message(), "authentication handshake failed")
+	}
+
+	return false
+}
+
+func (t *Dependency) isGRPCReady(ctx context.Context, verbose bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	conn, err := t.dialGRPC(ctx, t._url.Host)
+
+	if err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	defer conn.Close()
+
+	service := strings.TrimPrefix(t._url.Path, "/")
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+
+	if err != nil {
+		t.failed()
+
+		if grpcUnavailable(err) {
+			if verbose {
+				klog.Infof("Dependency %v not ready, %v", t, err)
+			}
+
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if response.Status != healthpb.HealthCheckResponse_SERVING {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, status:%v", t, response.Status)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// maxBodySize is the maximum size of a response body read by a probe
+const maxBodySize = 1024 * 1024
+
+func (t *Dependency) isValidHTTP() {
+	if _, err := statusInRange(http.StatusOK, t.statusRange()); err != nil {
+		klog.Fatalf("Unable to parse status value:%v for dependency %v, reason: %v", t.statusRange(), t, err)
+	}
+
+	for _, header := range append(t._options["header"], t._options["reqheader"]...) {
+		if !strings.Contains(header, ":") {
+			klog.Fatalf("Unable to parse header value:%v for dependency %v", header, t)
+		}
+	}
+
+	t.headerMatchers()
+	t.regexpOption("body")
+}
+
+// statusRange return the expected status range, default 200-399
+func (t *Dependency) statusRange() string {
+	if status := t.option("status"); status != "" {
+		return status
+	}
+
+	return "200-399"
+}
+
+// statusInRange check if the status code match a comma separated list of status or status range like 200-299
+func statusInRange(code int, statusRange string) (bool, error) {
+	for _, r := range strings.Split(statusRange, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(r), "-", 2)
+
+		low, err := strconv.Atoi(bounds[0])
+
+		if err != nil {
+			return false, err
+		}
+
+		high := low
+
+		if len(bounds) > 1 {
+			if high, err = strconv.Atoi(bounds[1]); err != nil {
+				return false, err
+			}
+		}
+
+		if code >= low && code <= high {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// headerMatchers return the response headers to match, the value is a regular expression
+func (t *Dependency) headerMatchers() map[string]*regexp.Regexp {
+	matchers := make(map[string]*regexp.Regexp)
+
+	for _, header := range t._options["header"] {
+		kv := strings.SplitN(header, ":", 2)
+		re, err := regexp.Compile(strings.TrimSpace(kv[1]))
+
+		if err != nil {
+			klog.Fatalf("Unable to parse header value:%v for dependency %v, reason: %v", header, t, err)
+		}
+
+		matchers[strings.TrimSpace(kv[0])] = re
+	}
+
+	return matchers
+}
+
+// newHTTPClient return an http client for the dependency
+func (t *Dependency) newHTTPClient() (*http.Client, error) {
+	tlsConfig, err := t.tlsConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: t.timeout(),
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   tlsConfig,
+			DisableKeepAlives: true,
+		},
+	}, nil
+}
+
+// serviceURL return the url of an API of the service, https is used with the option tls
+func (t *Dependency) serviceURL(path string, query url.Values) string {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     t._url.Host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+
+	if t.boolOption("tls") {
+		u.Scheme = "https"
+	}
+
+	return u.String()
+}
+
+// getJSON send a GET request and decode the JSON response, the status code is returned
+func (t *Dependency) getJSON(ctx context.Context, requestURL string, header http.Header, result interface{}) (int, error) {
+	client, err := t.newHTTPClient()
+
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for name, values := range header {
+		request.Header[name] = values
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	if err = json.NewDecoder(io.LimitReader(response.Body, maxBodySize)).Decode(result); err != nil {
+		return response.StatusCode, fmt.Errorf("Unable to decode response of %v, status:%d, reason: %v", t, response.StatusCode, err)
+	}
+
+	return response.StatusCode, nil
+}
+
+// requestURL return the url of the request without the options
+func (t *Dependency) requestURL() string {
+	u := *t._url
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return u.String()
+}
+
+func (t *Dependency) isHTTPReady(ctx context.Context, verbose bool) (bool, error) {
+	var client *http.Client
+	var request *http.Request
+	var response *http.Response
+	var body []byte
+	var err error
+
+	method := http.MethodGet
+
+	if m := t.option("method"); m != "" {
+		method = strings.ToUpper(m)
+	}
+
+	if client, err = t.newHTTPClient(); err != nil {
+		return false, err
+	}
+
+	if request, err = http.NewRequestWithContext(ctx, method, t.requestURL(), nil); err != nil {
+		return false, err
+	}
+
+	for _, header := range t._options["reqheader"] {
+		kv := strings.SplitN(header, ":", 2)
+		request.Header.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+
+	if response, err = client.Do(request); err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	defer response.Body.Close()
+
+	if body, err = io.ReadAll(io.LimitReader(response.Body, maxBodySize)); err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	if reason := t.httpNotReadyReason(response, body); reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
+
+// httpNotReadyReason return why the response doesn't match the expectations, empty if it match
+func (t *Dependency) httpNotReadyReason(response *http.Response, body []byte) string {
+	if ok, _ := statusInRange(response.StatusCode, t.statusRange()); !ok {
+		return fmt.Sprintf("status:%d not in range:%s", response.StatusCode, t.statusRange())
+	}
+
+	for name, re := range t.headerMatchers() {
+		value := response.Header.Get(name)
+
+		if !re.MatchString(value) {
+			return fmt.Sprintf("header %s:%s doesn't match %s", name, value, re)
+		}
+	}
+
+	if re := t.regexpOption("body"); re != nil && !re.Match(body) {
+		return fmt.Sprintf("body doesn't match %s", re)
+	}
+
+	return ""
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	klog "k8s.io/klog/v2"
+)
+
+// kafkaBrokers return the brokers listed in the host of the url, the port 9092 is added when missing
+func (t *Dependency) kafkaBrokers() []string {
+	brokers := make([]string, 0)
+
+	for _, broker := range strings.Split(t._url.Host, ",") {
+		if broker = strings.TrimSpace(broker); broker == "" {
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(broker); err != nil {
+			broker = net.JoinHostPort(strings.Trim(broker, "[]"), "9092")
+		}
+
+		brokers = append(brokers, broker)
+	}
+
+	return brokers
+}
+
+// kafkaMetadata fetch the metadata of the topics from a reachable broker.
+// The request never creates the topics, even if the brokers allow the auto creation.
+func (t *Dependency) kafkaMetadata(ctx context.Context, topics []string) (*kafka.MetadataResponse, error) {
+	var err error
+
+	transport := &kafka.Transport{
+		DialTimeout: t.timeout(),
+	}
+
+	if t.boolOption("tls") {
+		if transport.TLS, err = t.tlsConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	defer transport.CloseIdleConnections()
+
+	client := &kafka.Client{
+		Addr:      kafka.TCP(t.kafkaBrokers()...),
+		Timeout:   t.timeout(),
+		Transport: transport,
+	}
+
+	return client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+}
+
+// kafkaTopics return the topics listed in the path of the url
+func (t *Dependency) kafkaTopics() []string {
+	topics := make([]string, 0)
+
+	for _, topic := range strings.Split(strings.Trim(t._url.Path, "/"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	return topics
+}
+
+// kafkaNotFound return true if the error means the topic or its leader doesn't exist yet
+func kafkaNotFound(err error) bool {
+	return errors.Is(err, kafka.UnknownTopicOrPartition) || errors.Is(err, kafka.LeaderNotAvailable)
+}
+
+// kafkaNotReadyReason return why the cluster is not ready, empty if ready
+func (t *Dependency) kafkaNotReadyReason(metadata *kafka.MetadataResponse) (string, error) {
+	if metadata.Controller.Host == "" {
+		return "no controller elected", nil
+	}
+
+	minIsr := t.intOption("minIsr", 0)
+
+	for _, topic := range metadata.Topics {
+		if kafkaNotFound(topic.Error) {
+			return fmt.Sprintf("topic %s, %v", topic.Name, topic.Error), nil
+		} else if topic.Error != nil {
+			return "", fmt.Errorf("Unable to get metadata of topic %s, reason: %v", topic.Name, topic.Error)
+		}
+
+		if len(topic.Partitions) == 0 {
+			return fmt.Sprintf("topic %s doesn't exist", topic.Name), nil
+		}
+
+		for _, partition := range topic.Partitions {
+			if partition.Leader.Host == "" || kafkaNotFound(partition.Error) {
+				return fmt.Sprintf("topic %s partition %d has no leader", topic.Name, partition.ID), nil
+			}
+
+			if len(partition.Isr) < minIsr {
+				return fmt.Sprintf("topic %s partition %d has %d/%d in-sync replicas", topic.Name, partition.ID, len(partition.Isr), minIsr), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (t *Dependency) isKafkaReady(ctx context.Context, verbose bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	metadata, err := t.kafkaMetadata(ctx, t.kafkaTopics())
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	reason, err := t.kafkaNotReadyReason(metadata)
+
+	if err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	flags "github.com/jessevdk/go-flags"
+	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
@@ -45,6 +46,14 @@ const MaxInt = int(MaxUint >> 1)
 const MinInt = -MaxInt - 1
 
 var namespace = metav1.NamespaceSystem
+var strictReadiness = false
+var nodeName = os.Getenv("NODE_NAME")
+var podName = os.Getenv("POD_NAME")
+var podNamespace = os.Getenv("POD_NAMESPACE")
+var localZone = ""
+var zoneEndpoints = 0
+var probeTimeout = 5 * time.Second
+var connectEndpoints = 0
 
 func buildConfigFromEnvs(masterURL, kubeconfigPath string) (*restclient.Config, error) {
 	if kubeconfigPath == "" && masterURL == "" {
@@ -66,10 +75,11 @@ func mainExitCode(arguments []string) int {
 	klog.Infof("Start kubernetes dependencies version:%v, build at:%v", phVersion, phBuildDate)
 
 	args := Options{
-		MaxRetry:    "always",
-		IgnoreError: false,
-		Sleep:       "10s",
-		Timeout:     "300s",
+		MaxRetry:     "always",
+		IgnoreError:  false,
+		Sleep:        "10s",
+		Timeout:      "300s",
+		ProbeTimeout: "5s",
 	}
 
 	_, err := flags.ParseArgs(&args, arguments)
@@ -101,6 +111,8 @@ func mainExitCode(arguments []string) int {
 	maxRetry := args.getMaxRetry()
 	timeout := args.getTimeout()
 	sleep := args.getSleepTime()
+	probeTimeout = args.getProbeTimeout()
+	connectEndpoints = args.getConnectEndpoints()
 	ctx := NewContext(timeout)
 
 	defer ctx.Cancel()
@@ -114,6 +126,29 @@ func mainExitCode(arguments []string) int {
 		namespace = args.Namespace
 	}
 
+	strictReadiness = args.Strict
+
+	if args.ZoneEndpoints > 0 {
+		if nodeName == "" {
+			klog.Error("NODE_NAME environment variable is required to find the zone of this pod")
+			return -1
+		}
+
+		node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+
+		if err != nil {
+			klog.Errorf("Unable to get node %s: %v", nodeName, err)
+			return -1
+		}
+
+		if localZone = node.Labels[core.LabelTopologyZone]; localZone == "" {
+			klog.Errorf("Node %s doesn't have the label %s", nodeName, core.LabelTopologyZone)
+			return -1
+		}
+
+		zoneEndpoints = args.ZoneEndpoints
+	}
+
 	dependencies := makeDependencyList(maxRetry, args.Dep.Dependencies, args.IgnoreError)
 
 	var ready bool
@@ -0,0 +1,214 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	klog "k8s.io/klog/v2"
+)
+
+// mongoCommandNotFound is the error code returned by servers not supporting a command
+const mongoCommandNotFound = 59
+
+// MongoHello the reply of the hello command
+type MongoHello struct {
+	IsWritablePrimary bool   `bson:"isWritablePrimary"`
+	IsMaster          bool   `bson:"ismaster"`
+	SetName           string `bson:"setName"`
+	Primary           string `bson:"primary"`
+}
+
+// MongoReplicaSetStatus the reply of the replSetGetStatus command
+type MongoReplicaSetStatus struct {
+	Members []struct {
+		Name     string `bson:"name"`
+		StateStr string `bson:"stateStr"`
+	} `bson:"members"`
+}
+
+// mongoClientOptions return the client options of the dependency with the password
+func (t *Dependency) mongoClientOptions() (*options.ClientOptions, error) {
+	password, err := t.password()
+
+	if err != nil {
+		return nil, err
+	}
+
+	u := t.targetURL("primary", "secondaries")
+
+	if t._url.User != nil && password != "" {
+		u.User = url.UserPassword(t._url.User.Username(), password)
+	}
+
+	clientOptions := options.Client().
+		ApplyURI(u.String()).
+		SetConnectTimeout(t.timeout()).
+		SetServerSelectionTimeout(t.timeout())
+
+	if !strings.Contains(u.Host, ",") && u.Query().Get("replicaSet") == "" {
+		clientOptions.SetDirect(true)
+	}
+
+	if t.boolOption("tls") {
+		tlsConfig, err := t.tlsConfig()
+
+		if err != nil {
+			return nil, err
+		}
+
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	return clientOptions, clientOptions.Validate()
+}
+
+// mongoHello run the hello command, fallback to isMaster for servers not supporting hello
+func mongoHello(ctx context.Context, admin *mongo.Database) (*MongoHello, error) {
+	var commandError mongo.CommandError
+
+	hello := &MongoHello{}
+	err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(hello)
+
+	if errors.As(err, &commandError) && commandError.Code == mongoCommandNotFound {
+		err = admin.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(hello)
+	}
+
+	return hello, err
+}
+
+// mongoNotReadyReason return why the server is not ready, empty if ready
+func (t *Dependency) mongoNotReadyReason(ctx context.Context, admin *mongo.Database) (string, error) {
+	hello, err := mongoHello(ctx, admin)
+
+	if err != nil {
+		return "", err
+	}
+
+	if t.boolOption("primary") && !hello.IsWritablePrimary && !hello.IsMaster {
+		return "the server is not a writable primary", nil
+	}
+
+	secondaries := t.intOption("secondaries", 0)
+
+	if secondaries > 0 {
+		var numOfPrimary, numOfSecondary int
+
+		if hello.SetName == "" {
+			return "", fmt.Errorf("The server %v is not a member of a replica set", t)
+		}
+
+		status := &MongoReplicaSetStatus{}
+
+		if err = admin.RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(status); err != nil {
+			return "", err
+		}
+
+		for _, member := range status.Members {
+			switch member.StateStr {
+			case "PRIMARY":
+				numOfPrimary++
+			case "SECONDARY":
+				numOfSecondary++
+			}
+		}
+
+		if numOfPrimary == 0 {
+			return fmt.Sprintf("replica set %s has no primary", hello.SetName), nil
+		}
+
+		if numOfSecondary < secondaries {
+			return fmt.Sprintf("replica set %s has %d/%d secondaries", hello.SetName, numOfSecondary, secondaries), nil
+		}
+	}
+
+	return "", nil
+}
+
+// mongoUnreachable return true if no server was selected because the servers are not reachable yet
+func mongoUnreachable(err error) bool {
+	var selectionError topology.ServerSelectionError
+
+	if !errors.As(err, &selectionError) {
+		return isUnreachable(err)
+	}
+
+	for _, server := range selectionError.Desc.Servers {
+		if server.LastError != nil && !isUnreachable(server.LastError) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *Dependency) isMongoDBReady(ctx context.Context, verbose bool) (bool, error) {
+	clientOptions, err := t.mongoClientOptions()
+
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, clientOptions)
+
+	if err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	defer client.Disconnect(context.Background())
+
+	reason, err := t.mongoNotReadyReason(ctx, client.Database("admin"))
+
+	if err != nil {
+		t.failed()
+
+		if mongoUnreachable(err) {
+			if verbose {
+				klog.Infof("Dependency %v not ready, %v", t, err)
+			}
+
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
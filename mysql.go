@@ -0,0 +1,178 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	klog "k8s.io/klog/v2"
+)
+
+// mysqlTLSConfigName is the name of the TLS configuration registered for the driver
+const mysqlTLSConfigName = "dependency"
+
+// mysqlAddress return the address of the server, default port 3306
+func (t *Dependency) mysqlAddress() string {
+	if t._url.Port() == "" {
+		return net.JoinHostPort(t._url.Hostname(), "3306")
+	}
+
+	return t._url.Host
+}
+
+// mysqlDataSource return the DSN of the dependency for the driver
+func (t *Dependency) mysqlDataSource() (string, error) {
+	password, err := t.password()
+
+	if err != nil {
+		return "", err
+	}
+
+	config := mysql.NewConfig()
+	config.User = t._url.User.Username()
+	config.Passwd = password
+	config.Net = "tcp"
+	config.Addr = t.mysqlAddress()
+	config.DBName = strings.TrimPrefix(t._url.Path, "/")
+	config.Timeout = t.timeout()
+	config.ReadTimeout = t.timeout()
+	config.WriteTimeout = t.timeout()
+
+	if t.boolOption("tls") {
+		tlsConfig, err := t.tlsConfig()
+
+		if err != nil {
+			return "", err
+		}
+
+		if err = mysql.RegisterTLSConfig(mysqlTLSConfigName, tlsConfig); err != nil {
+			return "", err
+		}
+
+		config.TLSConfig = mysqlTLSConfigName
+	}
+
+	return config.FormatDSN(), nil
+}
+
+// mysqlHandshake read the initial handshake packet of the server without authentication
+func (t *Dependency) mysqlHandshake(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: t.timeout()}
+
+	conn, err := dialer.DialContext(ctx, "tcp", t.mysqlAddress())
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	header := make([]byte, 4)
+
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	payload := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+
+	if _, err = io.ReadFull(conn, payload); err != nil {
+		return err
+	}
+
+	if len(payload) == 0 {
+		return fmt.Errorf("Empty handshake received from %v", t)
+	}
+
+	switch payload[0] {
+	case 0x0a:
+		return nil
+	case 0xff:
+		if len(payload) < 3 {
+			return fmt.Errorf("Malformed error packet received from %v", t)
+		}
+
+		return fmt.Errorf("Error %d: %s", binary.LittleEndian.Uint16(payload[1:3]), payload[3:])
+	default:
+		return fmt.Errorf("Unsupported protocol version %d received from %v", payload[0], t)
+	}
+}
+
+func (t *Dependency) isMySQLReady(ctx context.Context, verbose bool) (bool, error) {
+	var readOnly bool
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	if t._url.User == nil {
+		if err := t.mysqlHandshake(ctx); err != nil {
+			return t.unreachable(err, verbose)
+		}
+
+		return t.succeeded(verbose), nil
+	}
+
+	dataSource, err := t.mysqlDataSource()
+
+	if err != nil {
+		return false, err
+	}
+
+	db, err := sql.Open("mysql", dataSource)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer db.Close()
+
+	if err = db.PingContext(ctx); err == nil && t.boolOption("writable") {
+		err = db.QueryRowContext(ctx, "SELECT @@global.read_only").Scan(&readOnly)
+	}
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	reason := "the server is read only"
+
+	if !readOnly {
+		reason = t.sqlNotReadyReason(ctx, db)
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
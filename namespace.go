@@ -0,0 +1,210 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	batch "k8s.io/api/batch/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog/v2"
+)
+
+// WorkloadProgress the progress of a workload found in a namespace
+type WorkloadProgress struct {
+	kind     string
+	name     string
+	ready    bool
+	progress string
+}
+
+func (w *WorkloadProgress) String() string {
+	return w.kind + "/" + w.name
+}
+
+// selfWorkload return the workload owning the pod running this check when it lives in the namespace of the dependency.
+// The pod is given by the environment variables POD_NAME and POD_NAMESPACE.
+func (t *Dependency) selfWorkload(ctx context.Context, client *clientset.Clientset) (string, error) {
+	if podName == "" || podNamespace != t._namespace {
+		return "", nil
+	}
+
+	pod, err := client.CoreV1().Pods(podNamespace).Get(ctx, podName, metav1.GetOptions{})
+
+	if err != nil {
+		return "", err
+	}
+
+	controller := metav1.GetControllerOf(pod)
+
+	if controller == nil {
+		return "", nil
+	}
+
+	switch controller.Kind {
+	case "ReplicaSet":
+		replicaset, err := client.AppsV1().ReplicaSets(podNamespace).Get(ctx, controller.Name, metav1.GetOptions{})
+
+		if err != nil {
+			return "", err
+		}
+
+		if controller = metav1.GetControllerOf(replicaset); controller != nil && controller.Kind == "Deployment" {
+			return "deploy/" + controller.Name, nil
+		}
+	case "StatefulSet":
+		return "sts/" + controller.Name, nil
+	case "DaemonSet":
+		return "ds/" + controller.Name, nil
+	case "Job":
+		return "job/" + controller.Name, nil
+	}
+
+	return "", nil
+}
+
+// namespaceWorkloads expand the namespace to every Deployment, StatefulSet, DaemonSet and Job matching the selector.
+// The workload owning the pod running this check is excluded, it can't be ready before the check ends.
+func (t *Dependency) namespaceWorkloads(ctx context.Context, client *clientset.Clientset) ([]*WorkloadProgress, error) {
+	listOptions := metav1.ListOptions{LabelSelector: t._selector}
+	workloads := make([]*WorkloadProgress, 0)
+
+	self, err := t.selfWorkload(ctx, client)
+
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := client.AppsV1().Deployments(t._namespace).List(ctx, listOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, &WorkloadProgress{
+			kind:     "deploy",
+			name:     deployment.Name,
+			ready:    deployment.Status.Replicas == deployment.Status.ReadyReplicas,
+			progress: fmt.Sprintf("%d/%d replicas ready", deployment.Status.ReadyReplicas, deployment.Status.Replicas),
+		})
+	}
+
+	statefulsets, err := client.AppsV1().StatefulSets(t._namespace).List(ctx, listOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stateful := range statefulsets.Items {
+		workloads = append(workloads, &WorkloadProgress{
+			kind:     "sts",
+			name:     stateful.Name,
+			ready:    stateful.Status.Replicas == stateful.Status.ReadyReplicas,
+			progress: fmt.Sprintf("%d/%d replicas ready", stateful.Status.ReadyReplicas, stateful.Status.Replicas),
+		})
+	}
+
+	daemonsets, err := client.AppsV1().DaemonSets(t._namespace).List(ctx, listOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, daemonset := range daemonsets.Items {
+		workloads = append(workloads, &WorkloadProgress{
+			kind:     "ds",
+			name:     daemonset.Name,
+			ready:    daemonset.Status.DesiredNumberScheduled == daemonset.Status.NumberReady,
+			progress: fmt.Sprintf("%d/%d pods ready", daemonset.Status.NumberReady, daemonset.Status.DesiredNumberScheduled),
+		})
+	}
+
+	jobs, err := client.BatchV1().Jobs(t._namespace).List(ctx, listOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+
+		if jobCondition(job, batch.JobFailed) {
+			return nil, fmt.Errorf("The job %v of dependency %v failed", job.Name, t)
+		}
+
+		completions := int32(1)
+
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+
+		workloads = append(workloads, &WorkloadProgress{
+			kind:     "job",
+			name:     job.Name,
+			ready:    jobCondition(job, batch.JobComplete),
+			progress: fmt.Sprintf("%d/%d completions", job.Status.Succeeded, completions),
+		})
+	}
+
+	result := make([]*WorkloadProgress, 0, len(workloads))
+
+	for _, workload := range workloads {
+		if workload.String() != self {
+			result = append(result, workload)
+		}
+	}
+
+	return result, nil
+}
+
+func jobCondition(job *batch.Job, conditionType batch.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == core.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Dependency) isNamespaceReady(ctx context.Context, client *clientset.Clientset, verbose bool) (bool, error) {
+	var numOfReady int
+
+	workloads, err := t.namespaceWorkloads(ctx, client)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, workload := range workloads {
+		if workload.ready {
+			numOfReady++
+		}
+
+		if verbose {
+			klog.Infof("Namespace %v, %v ready:%v, %s", t, workload, workload.ready, workload.progress)
+		}
+	}
+
+	klog.Infof("Namespace %v, %d/%d workloads ready", t, numOfReady, len(workloads))
+
+	return len(workloads) > 0 && numOfReady == len(workloads), nil
+}
@@ -25,16 +25,20 @@ import (
 
 // Options arguments
 type Options struct {
-	Namespace   string                          `short:"n" long:"namespace" description:"Default namespace"`
-	Kubeconfig  string                          `short:"k" long:"kubeconfig" description:"Kubeconfig file"`
-	Apiserver   string                          `short:"a" long:"apiserver" description:"apiserver host"`
-	MaxRetry    string                          `short:"r" long:"maxretry" description:"[always|The number of retry before a depency is considered as unready]"`
-	KeepOnError bool                            `short:"b" long:"keeponerror" description:"Try always to reach the dependency"`
-	IgnoreError bool                            `short:"i" long:"ignoreerror" description:"Ignore error"`
-	Verbose     bool                            `short:"v" long:"verbose" description:"Verbose"`
-	Sleep       string                          `short:"s" long:"sleep" description:"Time interval in time.Duration unit"`
-	Timeout     string                          `short:"t" long:"timeout" description:"Time to wait before to declare service down in time.Duration unit"`
-	Dep         struct{ Dependencies []string } `positional-args:"yes" required:"1" positional-arg-name:"dependency" description:"Enumeration of dependency service"`
+	Namespace     string                          `short:"n" long:"namespace" description:"Default namespace"`
+	Kubeconfig    string                          `short:"k" long:"kubeconfig" description:"Kubeconfig file"`
+	Apiserver     string                          `short:"a" long:"apiserver" description:"apiserver host"`
+	MaxRetry      string                          `short:"r" long:"maxretry" description:"[always|The number of retry before a depency is considered as unready]"`
+	KeepOnError   bool                            `short:"b" long:"keeponerror" description:"Try always to reach the dependency"`
+	IgnoreError   bool                            `short:"i" long:"ignoreerror" description:"Ignore error"`
+	Verbose       bool                            `short:"v" long:"verbose" description:"Verbose"`
+	Strict        bool                            `long:"strict" description:"Require every pod owned by a deploy, rs, rc or sts dependency to be ready"`
+	ZoneEndpoints int                             `long:"zone-endpoints" description:"Number of ready endpoints required in the zone of the node running this pod for svc dependencies"`
+	Connect       string                          `long:"connect" description:"[all|The number of ready endpoints of a svc dependency to dial]"`
+	Sleep         string                          `short:"s" long:"sleep" description:"Time interval in time.Duration unit"`
+	ProbeTimeout  string                          `long:"probe-timeout" description:"Time to wait for a single attempt of a network dependency in time.Duration unit"`
+	Timeout       string                          `short:"t" long:"timeout" description:"Time to wait before to declare service down in time.Duration unit"`
+	Dep           struct{ Dependencies []string } `positional-args:"yes" required:"1" positional-arg-name:"dependency" description:"Enumeration of dependency service"`
 }
 
 func (args *Options) getMaxRetry() int {
@@ -52,6 +56,21 @@ func (args *Options) getMaxRetry() int {
 	return maxRetry
 }
 
+func (args *Options) getConnectEndpoints() int {
+	var connect int
+	var err error
+
+	if args.Connect == "all" {
+		connect = MaxInt
+	} else if args.Connect == "" {
+		connect = 0
+	} else if connect, err = strconv.Atoi(args.Connect); err != nil {
+		klog.Fatalf("Unable to parse connect value:%v", args.Connect)
+	}
+
+	return connect
+}
+
 func (args *Options) getTimeout() time.Duration {
 	timeout, err := time.ParseDuration(args.Timeout)
 	if err != nil {
@@ -61,6 +80,15 @@ func (args *Options) getTimeout() time.Duration {
 	return timeout
 }
 
+func (args *Options) getProbeTimeout() time.Duration {
+	timeout, err := time.ParseDuration(args.ProbeTimeout)
+	if err != nil {
+		klog.Fatalf("Unable to parse probe-timeout value:%v", args.ProbeTimeout)
+	}
+
+	return timeout
+}
+
 func (args *Options) getSleepTime() time.Duration {
 	sleep, err := time.ParseDuration(args.Sleep)
 	if err != nil {
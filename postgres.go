@@ -0,0 +1,118 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/lib/pq"
+	klog "k8s.io/klog/v2"
+)
+
+// pgCannotConnectNow is the error code returned while the server is starting up
+const pgCannotConnectNow = "57P03"
+
+// postgresDataSource return the connection url of the dependency with the password
+func (t *Dependency) postgresDataSource() (string, error) {
+	password, err := t.password()
+
+	if err != nil {
+		return "", err
+	}
+
+	u := t.targetURL("primary", "query", "op", "value", "type")
+	query := u.Query()
+
+	if t._url.User != nil {
+		if password != "" {
+			u.User = url.UserPassword(t._url.User.Username(), password)
+		} else {
+			u.User = url.User(t._url.User.Username())
+		}
+	}
+
+	if query.Get("connect_timeout") == "" {
+		query.Set("connect_timeout", strconv.Itoa(int(t.timeout().Seconds())))
+	}
+
+	u.Scheme = "postgres"
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func (t *Dependency) isPostgresReady(ctx context.Context, verbose bool) (bool, error) {
+	var pgError *pq.Error
+	var inRecovery bool
+
+	dataSource, err := t.postgresDataSource()
+
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	db, err := sql.Open("postgres", dataSource)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer db.Close()
+
+	if err = db.QueryRowContext(ctx, "SELECT 1").Err(); err == nil && t.boolOption("primary") {
+		err = db.QueryRowContext(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery)
+	}
+
+	if err != nil {
+		if errors.As(err, &pgError) && pgError.Code == pgCannotConnectNow {
+			t.failed()
+
+			if verbose {
+				klog.Infof("Dependency %v not ready, %v", t, err)
+			}
+
+			return false, nil
+		}
+
+		return t.unreachable(err, verbose)
+	}
+
+	reason := "the server is in recovery"
+
+	if !inRecovery {
+		reason = t.sqlNotReadyReason(ctx, db)
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+func (t *Dependency) isValidURL() {
+	switch t._kind {
+	case "tcp", "grpc":
+		if t._url.Port() == "" {
+			klog.Fatalf("Port not defined for dependency %v", t)
+		}
+	case "tls":
+		if t._url.Port() == "" {
+			klog.Fatalf("Port not defined for dependency %v", t)
+		}
+
+		t.durationOption("minValidity", 0)
+
+	case "http", "https":
+		t.isValidHTTP()
+	case "dns":
+		t.isValidDNS()
+	case "postgres", "postgresql":
+		t.boolOption("primary")
+		t.isValidSQL()
+	case "mysql":
+		t.boolOption("writable")
+		t.isValidSQL()
+	case "redis", "rediss":
+		t.isValidRedis()
+	case "mongodb":
+		t.boolOption("primary")
+		t.intOption("secondaries", 0)
+	case "kafka":
+		t.intOption("minIsr", 0)
+	case "amqp", "amqps":
+	case "es":
+		t.isValidElasticsearch()
+	case "etcd":
+	case "prom":
+		t.isValidPrometheus()
+	case "vault":
+		t.isValidVault()
+	case "zk":
+	case "consul":
+		t.isValidConsul()
+	case "file", "unix":
+		if t._url.Path == "" {
+			klog.Fatalf("Path not defined for dependency %v", t)
+		}
+
+		t.regexpOption("content")
+	default:
+		klog.Fatalf("Unknown dependency type %v", t._kind)
+	}
+
+	t.intOption("success", 1)
+	t.timeout()
+}
+
+// probeOptions are the options handled by the probes and never sent to the dependency
+var probeOptions = []string{"timeout", "success", "usernameFile", "passwordFile", "passwordEnv", "tls", "ca", "cert", "key", "insecure", "servername"}
+
+// targetURL return the url of the dependency without the options handled by the probe
+func (t *Dependency) targetURL(options ...string) *url.URL {
+	u := *t._url
+	query := u.Query()
+
+	for _, name := range append(probeOptions, options...) {
+		query.Del(name)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return &u
+}
+
+// username return the user of the dependency read from the file given by the option usernameFile or the url
+func (t *Dependency) username() (string, error) {
+	if t.option("usernameFile") != "" {
+		return t.readSecret("usernameFile")
+	}
+
+	return t._url.User.Username(), nil
+}
+
+// password return the password of the dependency read from the file given by the option passwordFile,
+// the environment variable given by the option passwordEnv or the url
+func (t *Dependency) password() (string, error) {
+	if t.option("passwordFile") != "" {
+		return t.readSecret("passwordFile")
+	}
+
+	if env := t.option("passwordEnv"); env != "" {
+		password, found := os.LookupEnv(env)
+
+		if !found {
+			return "", fmt.Errorf("The environment variable %v is not defined for dependency %v", env, t)
+		}
+
+		return password, nil
+	}
+
+	password, _ := t._url.User.Password()
+
+	return password, nil
+}
+
+// readSecret return the content of a file given by an option, like a mounted secret
+func (t *Dependency) readSecret(name string) (string, error) {
+	file := t.option(name)
+
+	if file == "" {
+		return "", nil
+	}
+
+	secret, err := os.ReadFile(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(secret), "\r\n"), nil
+}
+
+// option return the value of an option of the dependency
+func (t *Dependency) option(name string) string {
+	return t._options.Get(name)
+}
+
+// intOption return the value of an integer option of the dependency
+func (t *Dependency) intOption(name string, defaultValue int) int {
+	value := t.option(name)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	result, err := strconv.Atoi(value)
+
+	if err != nil {
+		klog.Fatalf("Unable to parse %s value:%v for dependency %v", name, value, t)
+	}
+
+	return result
+}
+
+// regexpOption return the compiled value of a regular expression option of the dependency
+func (t *Dependency) regexpOption(name string) *regexp.Regexp {
+	value := t.option(name)
+
+	if value == "" {
+		return nil
+	}
+
+	result, err := regexp.Compile(value)
+
+	if err != nil {
+		klog.Fatalf("Unable to parse %s value:%v for dependency %v, reason: %v", name, value, t, err)
+	}
+
+	return result
+}
+
+// boolOption return the value of a boolean option of the dependency
+func (t *Dependency) boolOption(name string) bool {
+	value := t.option(name)
+
+	if value == "" {
+		return false
+	}
+
+	result, err := strconv.ParseBool(value)
+
+	if err != nil {
+		klog.Fatalf("Unable to parse %s value:%v for dependency %v", name, value, t)
+	}
+
+	return result
+}
+
+// durationOption return the value of a time.Duration option of the dependency
+func (t *Dependency) durationOption(name string, defaultValue time.Duration) time.Duration {
+	value := t.option(name)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	result, err := time.ParseDuration(value)
+
+	if err != nil {
+		klog.Fatalf("Unable to parse %s value:%v for dependency %v", name, value, t)
+	}
+
+	return result
+}
+
+// timeout return the time to wait for a single attempt of the dependency
+func (t *Dependency) timeout() time.Duration {
+	return t.durationOption("timeout", probeTimeout)
+}
+
+// succeeded count the consecutive successful attempts and return true when the required count is reached
+func (t *Dependency) succeeded(verbose bool) bool {
+	t._successes++
+
+	required := t.intOption("success", 1)
+
+	if verbose {
+		klog.Infof("Dependency %v, %d/%d consecutive successful attempts", t, t._successes, required)
+	}
+
+	return t._successes >= required
+}
+
+// failed reset the count of consecutive successful attempts
+func (t *Dependency) failed() {
+	t._successes = 0
+}
+
+// isUnreachable return true if the error means the dependency is not listening or not resolvable yet
+func isUnreachable(err error) bool {
+	var dnsError *net.DNSError
+	var netError net.Error
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if errors.As(err, &dnsError) && dnsError.IsNotFound {
+		return true
+	}
+
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+// unreachable report a dependency not reachable yet as not ready, other errors are returned
+func (t *Dependency) unreachable(err error, verbose bool) (bool, error) {
+	t.failed()
+
+	if !isUnreachable(err) {
+		return false, err
+	}
+
+	if verbose {
+		klog.Infof("Dependency %v not ready, %v", t, err)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	klog "k8s.io/klog/v2"
+)
+
+// PrometheusResponse the response of an instant query
+type PrometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// PrometheusSample a sample of a vector
+type PrometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+func (t *Dependency) isValidPrometheus() {
+	if t.option("query") == "" {
+		klog.Fatalf("Query not defined for dependency %v", t)
+	}
+
+	t.isValidComparison()
+}
+
+// prometheusSamples return the values of the samples of the result, keyed by the metric
+func prometheusSamples(response *PrometheusResponse) (map[string]string, error) {
+	samples := make(map[string]string)
+
+	switch response.Data.ResultType {
+	case "vector":
+		var vector []PrometheusSample
+
+		if err := json.Unmarshal(response.Data.Result, &vector); err != nil {
+			return nil, err
+		}
+
+		for _, sample := range vector {
+			if len(sample.Value) == 2 {
+				samples[fmt.Sprintf("%v", sample.Metric)] = fmt.Sprintf("%v", sample.Value[1])
+			}
+		}
+	case "scalar":
+		var scalar []interface{}
+
+		if err := json.Unmarshal(response.Data.Result, &scalar); err != nil {
+			return nil, err
+		}
+
+		if len(scalar) == 2 {
+			samples["scalar"] = fmt.Sprintf("%v", scalar[1])
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported result type %v", response.Data.ResultType)
+	}
+
+	return samples, nil
+}
+
+// prometheusNotReadyReason return why the query doesn't satisfy the comparison, empty if every sample satisfy it
+func (t *Dependency) prometheusNotReadyReason(response *PrometheusResponse) (string, error) {
+	samples, err := prometheusSamples(response)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(samples) == 0 {
+		return "empty result", nil
+	}
+
+	expected := t.option("value")
+	op := t.comparisonOperator()
+
+	for metric, value := range samples {
+		if ok, err := compareValue(value, op, expected, t.option("type")); err != nil {
+			return "", err
+		} else if !ok {
+			return fmt.Sprintf("sample %s is %s, expected %s %s", metric, value, op, expected), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (t *Dependency) isPrometheusReady(ctx context.Context, verbose bool) (bool, error) {
+	var response PrometheusResponse
+
+	header := http.Header{}
+	token, err := t.readSecret("tokenFile")
+
+	if err != nil {
+		return false, err
+	}
+
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	code, err := t.getJSON(ctx, t.serviceURL("/api/v1/query", url.Values{"query": []string{t.option("query")}}), header, &response)
+
+	// The server is starting, the body is not JSON
+	if code >= http.StatusInternalServerError {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, status:%d", t, code)
+		}
+
+		return false, nil
+	}
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	if response.Status != "success" {
+		t.failed()
+
+		return false, fmt.Errorf("Query of %v failed, status:%d, reason: %s", t, code, response.Error)
+	}
+
+	reason, err := t.prometheusNotReadyReason(&response)
+
+	if err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
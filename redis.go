@@ -0,0 +1,259 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// RedisError an error reply of the server
+type RedisError string
+
+func (e RedisError) Error() string {
+	return string(e)
+}
+
+// RedisConn a connection to a redis server speaking RESP
+type RedisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// command send a command and return the reply
+func (c *RedisConn) command(args ...string) (interface{}, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	return c.readReply()
+}
+
+// readReply read a RESP reply, an error reply is returned as RedisError
+func (c *RedisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+
+	if len(line) == 0 {
+		return nil, fmt.Errorf("Malformed redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+
+		if err != nil || size < 0 {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2)
+
+		if _, err = io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+
+		if err != nil || size < 0 {
+			return nil, err
+		}
+
+		array := make([]interface{}, size)
+
+		for i := range array {
+			if array[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+
+		return array, nil
+	default:
+		return nil, fmt.Errorf("Unsupported redis reply: %s", line)
+	}
+}
+
+func (t *Dependency) isValidRedis() {
+	switch t.option("role") {
+	case "", "master", "replica":
+	default:
+		klog.Fatalf("Unknown role %v for dependency %v", t.option("role"), t)
+	}
+}
+
+// dialRedis open a connection to the server and authenticate if a password is defined
+func (t *Dependency) dialRedis(ctx context.Context) (*RedisConn, error) {
+	var conn net.Conn
+	var err error
+
+	password, err := t.password()
+
+	if err != nil {
+		return nil, err
+	}
+
+	address := t._url.Host
+
+	if t._url.Port() == "" {
+		address = net.JoinHostPort(t._url.Hostname(), "6379")
+	}
+
+	if t._kind == "rediss" || t.boolOption("tls") {
+		tlsConfig, err := t.tlsConfig()
+
+		if err != nil {
+			return nil, err
+		}
+
+		dialer := tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		dialer := net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c := &RedisConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+
+	if password != "" {
+		if user := t._url.User.Username(); user != "" {
+			_, err = c.command("AUTH", user, password)
+		} else {
+			_, err = c.command("AUTH", password)
+		}
+
+		if err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// redisNotReadyReason return why the server is not ready, empty if ready
+func (t *Dependency) redisNotReadyReason(c *RedisConn) (string, error) {
+	var redisError RedisError
+
+	if _, err := c.command("PING"); err != nil {
+		if errors.As(err, &redisError) && strings.HasPrefix(string(redisError), "LOADING") {
+			return string(redisError), nil
+		}
+
+		return "", err
+	}
+
+	switch t.option("role") {
+	case "master":
+		reply, err := c.command("ROLE")
+
+		if err != nil {
+			return "", err
+		}
+
+		if role, ok := reply.([]interface{}); !ok || len(role) == 0 {
+			return "", fmt.Errorf("Malformed ROLE reply from %v", t)
+		} else if role[0] != "master" {
+			return fmt.Sprintf("role is %v", role[0]), nil
+		}
+	case "replica":
+		reply, err := c.command("INFO", "replication")
+
+		if err != nil {
+			return "", err
+		}
+
+		if info, ok := reply.(string); !ok || !strings.Contains(info, "master_link_status:up") {
+			return "master_link_status is not up", nil
+		}
+	}
+
+	return "", nil
+}
+
+func (t *Dependency) isRedisReady(ctx context.Context, verbose bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	c, err := t.dialRedis(ctx)
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	defer c.conn.Close()
+
+	reason, err := t.redisNotReadyReason(c)
+
+	if err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
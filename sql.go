@@ -0,0 +1,100 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	klog "k8s.io/klog/v2"
+)
+
+func (t *Dependency) isValidSQL() {
+	if t.option("query") == "" {
+		return
+	}
+
+	if t._kind == "mysql" && t._url.User == nil {
+		klog.Fatalf("A user is required to run the query of dependency %v", t)
+	}
+
+	t.isValidComparison()
+}
+
+// isValidComparison check the options comparing the result of a query
+func (t *Dependency) isValidComparison() {
+	if _, found := t._options["value"]; !found {
+		klog.Fatalf("Value not defined for dependency %v", t)
+	}
+
+	if !validOperator(t.comparisonOperator()) {
+		klog.Fatalf("Unknown operator %v for dependency %v", t.option("op"), t)
+	}
+
+	if !validValueType(t.option("type")) {
+		klog.Fatalf("Unknown type %v for dependency %v", t.option("type"), t)
+	}
+}
+
+// comparisonOperator return the operator used to compare the result of a query, default =
+func (t *Dependency) comparisonOperator() string {
+	if op := t.option("op"); op != "" {
+		return op
+	}
+
+	return "="
+}
+
+// sqlNotReadyReason run the read-only query returning one scalar and compare the result to the expected value,
+// return why the dependency is not ready, empty if ready or if no query is defined
+func (t *Dependency) sqlNotReadyReason(ctx context.Context, db *sql.DB) string {
+	var value sql.NullString
+
+	query := t.option("query")
+
+	if query == "" {
+		return ""
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+
+	if err != nil {
+		return err.Error()
+	}
+
+	defer tx.Rollback()
+
+	if err = tx.QueryRowContext(ctx, query).Scan(&value); err != nil {
+		return fmt.Sprintf("query failed: %v", err)
+	}
+
+	if !value.Valid {
+		return "query returned NULL"
+	}
+
+	expected := t.option("value")
+	op := t.comparisonOperator()
+
+	if ok, err := compareValue(value.String, op, expected, t.option("type")); err != nil {
+		return err.Error()
+	} else if !ok {
+		return fmt.Sprintf("query returned %s, expected %s %s", value.String, op, expected)
+	}
+
+	return ""
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"net"
+)
+
+func (t *Dependency) isTCPReady(ctx context.Context, verbose bool) (bool, error) {
+	dialer := net.Dialer{Timeout: t.timeout()}
+
+	conn, err := dialer.DialContext(ctx, "tcp", t._url.Host)
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	conn.Close()
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+)
+
+// describeCertificate return a description of the certificate for error messages
+func describeCertificate(cert *x509.Certificate) string {
+	return fmt.Sprintf("subject:%q, issuer:%q, serial:%v, dns names:%v, valid from:%v to:%v",
+		cert.Subject.String(), cert.Issuer.String(), cert.SerialNumber, cert.DNSNames,
+		cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+}
+
+// subjectAlternativeName return the name expected in the certificate, default to the server name or the host
+func (t *Dependency) subjectAlternativeName() string {
+	if san := t.option("san"); san != "" {
+		return san
+	}
+
+	if servername := t.option("servername"); servername != "" {
+		return servername
+	}
+
+	return t._url.Hostname()
+}
+
+// verifyCertificates check the chain, the subject alternative name and the remaining lifetime of the certificates
+func (t *Dependency) verifyCertificates(certs []*x509.Certificate) error {
+	var roots *x509.CertPool
+	var err error
+
+	if len(certs) == 0 {
+		return fmt.Errorf("No certificate presented by %v", t)
+	}
+
+	leaf := certs[0]
+
+	if ca := t.option("ca"); ca != "" {
+		if roots, err = loadCertPool(ca); err != nil {
+			return err
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	if !t.boolOption("insecure") {
+		if _, err = leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+			return fmt.Errorf("The certificate of %v is not valid, %s, reason: %v", t, describeCertificate(leaf), err)
+		}
+	}
+
+	if err = leaf.VerifyHostname(t.subjectAlternativeName()); err != nil {
+		return fmt.Errorf("The certificate of %v doesn't match, %s, reason: %v", t, describeCertificate(leaf), err)
+	}
+
+	minValidity := t.durationOption("minValidity", 0)
+
+	for _, cert := range certs {
+		if remaining := time.Until(cert.NotAfter); remaining < minValidity {
+			return fmt.Errorf("The certificate of %v expires in %v, less than %v, %s", t, remaining.Round(time.Second), minValidity, describeCertificate(cert))
+		}
+	}
+
+	return nil
+}
+
+func (t *Dependency) isTLSReady(ctx context.Context, verbose bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{},
+		Config: &tls.Config{
+			ServerName: t.option("servername"),
+			// The certificates are verified after the handshake to describe the failing certificate
+			InsecureSkipVerify: true,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", t._url.Host)
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	defer conn.Close()
+
+	if err = t.verifyCertificates(conn.(*tls.Conn).ConnectionState().PeerCertificates); err != nil {
+		t.failed()
+
+		return false, err
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// tlsConfig return the TLS configuration of the dependency built from the options ca, cert, key, insecure and servername
+func (t *Dependency) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         t.option("servername"),
+		InsecureSkipVerify: t.boolOption("insecure"),
+	}
+
+	if ca := t.option("ca"); ca != "" {
+		pool, err := loadCertPool(ca)
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = pool
+	}
+
+	if cert := t.option("cert"); cert != "" {
+		certificate, err := tls.LoadX509KeyPair(cert, t.option("key"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
+
+// loadCertPool return a certificate pool from a PEM bundle
+func loadCertPool(file string) (*x509.CertPool, error) {
+	bundle, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("No certificate found in CA bundle %v", file)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	klog "k8s.io/klog/v2"
+)
+
+// VaultHealth the response of /v1/sys/health
+type VaultHealth struct {
+	Initialized        bool   `json:"initialized"`
+	Sealed             bool   `json:"sealed"`
+	Standby            bool   `json:"standby"`
+	PerformanceStandby bool   `json:"performance_standby"`
+	Version            string `json:"version"`
+}
+
+func (t *Dependency) isValidVault() {
+	for _, name := range []string{"standbyok", "perfstandbyok", "sealedok", "uninitok"} {
+		t.boolOption(name)
+	}
+}
+
+// vaultState return the state of the server and if this state is ready according to the policy
+func (t *Dependency) vaultState(health *VaultHealth) (string, bool) {
+	switch {
+	case !health.Initialized:
+		return "uninitialized", t.boolOption("uninitok")
+	case health.Sealed:
+		return "sealed", t.boolOption("sealedok")
+	case health.PerformanceStandby:
+		return "performance standby", t.boolOption("perfstandbyok")
+	case health.Standby:
+		return "standby", t.boolOption("standbyok")
+	}
+
+	return "active", true
+}
+
+func (t *Dependency) isVaultReady(ctx context.Context, verbose bool) (bool, error) {
+	var health VaultHealth
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	// All the states are reported with a status code 200 to read the body
+	query := url.Values{
+		"standbyok":     []string{"true"},
+		"perfstandbyok": []string{"true"},
+		"sealedcode":    []string{"200"},
+		"uninitcode":    []string{"200"},
+	}
+
+	if _, err := t.getJSON(ctx, t.serviceURL("/v1/sys/health", query), http.Header{}, &health); err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	state, ready := t.vaultState(&health)
+
+	if verbose {
+		klog.Infof("Dependency %v, version:%s state:%s", t, health.Version, state)
+	}
+
+	if !ready {
+		t.failed()
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog/v2"
+)
+
+// workloadReady check the pods owned by a workload when strict readiness is required
+// or when verbose is set and the status counters say the workload is not ready
+func (t *Dependency) workloadReady(ready bool, desired int32, verbose bool, listPods func() ([]core.Pod, error)) (bool, error) {
+	if !strictReadiness && (ready || !verbose) {
+		return ready, nil
+	}
+
+	pods, err := listPods()
+
+	if err != nil {
+		return false, err
+	}
+
+	podsReady := t.podsReady(pods, desired, verbose)
+
+	if strictReadiness {
+		return ready && podsReady, nil
+	}
+
+	return ready, nil
+}
+
+// desiredReplicas return the number of replicas wanted by the workload spec, 1 if not set
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+
+	return *replicas
+}
+
+// podsReady return true if all pods are ready, report the reason why a pod is not ready when verbose.
+// No pod is required when the workload is scaled to zero.
+func (t *Dependency) podsReady(pods []core.Pod, desired int32, verbose bool) bool {
+	numOfReady := 0
+
+	for i := range pods {
+		pod := &pods[i]
+
+		if ready, reason := t.podReadyReason(pod); ready {
+			numOfReady++
+
+			if verbose {
+				klog.Infof("Dependency %v, pod:%v is ready", t, pod.Name)
+			}
+		} else if verbose {
+			klog.Infof("Dependency %v, pod:%v not ready, %s", t, pod.Name, reason)
+		}
+	}
+
+	if verbose {
+		klog.Infof("Dependency %v, %d/%d pods ready", t, numOfReady, len(pods))
+	}
+
+	return (desired == 0 || len(pods) > 0) && numOfReady == len(pods)
+}
+
+// deploymentPods walk the replicasets owned by the deployment and return their pods
+func (t *Dependency) deploymentPods(ctx context.Context, client *clientset.Clientset, deployment *apps.Deployment) ([]core.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+
+	if err != nil {
+		return nil, err
+	}
+
+	replicasets, err := client.AppsV1().ReplicaSets(t._namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]types.UID, 0, len(replicasets.Items))
+
+	for i := range replicasets.Items {
+		replicaset := &replicasets.Items[i]
+
+		if metav1.IsControlledBy(replicaset, deployment) {
+			owners = append(owners, replicaset.UID)
+		}
+	}
+
+	return t.ownedPods(ctx, client, deployment.Spec.Selector, owners...)
+}
+
+// ownedPods return the pods matching the selector and controlled by one of the owners
+func (t *Dependency) ownedPods(ctx context.Context, client *clientset.Clientset, labelSelector *metav1.LabelSelector, owners ...types.UID) ([]core.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.CoreV1().Pods(t._namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]core.Pod, 0, len(pods.Items))
+
+	for _, pod := range pods.Items {
+		if controller := metav1.GetControllerOf(&pod); controller != nil {
+			for _, owner := range owners {
+				if controller.UID == owner {
+					result = append(result, pod)
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Fred78290.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	klog "k8s.io/klog/v2"
+)
+
+// errNotInWhitelist the four letter word is not allowed by 4lw.commands.whitelist
+var errNotInWhitelist = errors.New("not in the whitelist")
+
+// zookeeperModes return the modes accepted as ready, default leader and follower
+func (t *Dependency) zookeeperModes() []string {
+	if modes := t.option("mode"); modes != "" {
+		return strings.Split(modes, ",")
+	}
+
+	return []string{"leader", "follower"}
+}
+
+// zookeeperAddress return the address of the server, default port 2181
+func (t *Dependency) zookeeperAddress() string {
+	if t._url.Port() == "" {
+		return net.JoinHostPort(t._url.Hostname(), "2181")
+	}
+
+	return t._url.Host
+}
+
+// fourLetterWord send a four letter word command and return the response
+func (t *Dependency) fourLetterWord(ctx context.Context, command string) (string, error) {
+	dialer := net.Dialer{}
+
+	conn, err := dialer.DialContext(ctx, "tcp", t.zookeeperAddress())
+
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err = io.WriteString(conn, command); err != nil {
+		return "", err
+	}
+
+	response, err := io.ReadAll(io.LimitReader(conn, maxBodySize))
+
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(string(response), "not in the whitelist") {
+		return "", fmt.Errorf("The command %s is not allowed by %v, %w", command, t, errNotInWhitelist)
+	}
+
+	return string(response), nil
+}
+
+// zookeeperNotReadyReason return why the server is not ready, empty if ready.
+// ruok is not allowed by default since ZooKeeper 3.5, the mode given by srvr is enough.
+func (t *Dependency) zookeeperNotReadyReason(ctx context.Context) (string, error) {
+	response, err := t.fourLetterWord(ctx, "ruok")
+
+	if err == nil {
+		if strings.TrimSpace(response) != "imok" {
+			return "ruok answered " + strings.TrimSpace(response), nil
+		}
+	} else if !errors.Is(err, errNotInWhitelist) {
+		return "", err
+	}
+
+	if response, err = t.fourLetterWord(ctx, "srvr"); err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(response, "\n") {
+		if mode := strings.TrimPrefix(line, "Mode:"); mode != line {
+			mode = strings.TrimSpace(mode)
+
+			for _, expected := range t.zookeeperModes() {
+				if mode == expected {
+					return "", nil
+				}
+			}
+
+			return "mode is " + mode, nil
+		}
+	}
+
+	return strings.TrimSpace(response), nil
+}
+
+func (t *Dependency) isZookeeperReady(ctx context.Context, verbose bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout())
+	defer cancel()
+
+	reason, err := t.zookeeperNotReadyReason(ctx)
+
+	if err != nil {
+		return t.unreachable(err, verbose)
+	}
+
+	if reason != "" {
+		t.failed()
+
+		if verbose {
+			klog.Infof("Dependency %v not ready, %s", t, reason)
+		}
+
+		return false, nil
+	}
+
+	return t.succeeded(verbose), nil
+}